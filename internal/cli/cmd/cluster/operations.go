@@ -18,20 +18,31 @@ package cluster
 
 import (
 	"context"
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/hcl"
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/diff"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/templates"
+	"gopkg.in/ini.v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 
+	dataprotectionv1alpha1 "github.com/apecloud/kubeblocks/apis/dataprotection/v1alpha1"
 	dbaasv1alpha1 "github.com/apecloud/kubeblocks/apis/dbaas/v1alpha1"
 	"github.com/apecloud/kubeblocks/internal/cli/create"
 	"github.com/apecloud/kubeblocks/internal/cli/delete"
@@ -55,6 +66,24 @@ type OperationsOptions struct {
 	// Upgrade options
 	ClusterVersionRef string `json:"clusterVersionRef"`
 
+	// Progressive/canary rollout options
+	CanaryReplicas int `json:"canaryReplicas,omitempty"`
+	CanaryPercent  int `json:"canaryPercent,omitempty"`
+	// PauseBetween is parsed from the --pause-between flag; PauseBetweenSeconds, derived from it in
+	// validateRolloutStrategy, is what actually gets rendered into the OpsRequest, since
+	// time.Duration has no custom JSON marshaling and would otherwise serialize as raw nanoseconds.
+	PauseBetween          time.Duration `json:"-"`
+	PauseBetweenSeconds   int           `json:"pauseBetween,omitempty"`
+	MaxSurge              string        `json:"maxSurge,omitempty"`
+	MaxUnavailable        string        `json:"maxUnavailable,omitempty"`
+	AutoRollbackOnFailure bool          `json:"autoRollbackOnFailure,omitempty"`
+	ResumeOpsName         string        `json:"-"`
+	AbortOpsName          string        `json:"-"`
+
+	// Preview options, shared by every operations subcommand
+	DryRun string `json:"-"`
+	Output string `json:"-"`
+
 	// VerticalScaling options
 	RequestCPU    string `json:"requestCPU"`
 	RequestMemory string `json:"requestMemory"`
@@ -65,16 +94,34 @@ type OperationsOptions struct {
 	Replicas int `json:"replicas"`
 
 	// Reconfiguring options
-	URLPath         string            `json:"urlPath"`
-	Parameters      []string          `json:"parameters"`
-	KeyValues       map[string]string `json:"keyValues"`
-	CfgTemplateName string            `json:"cfgTemplateName"`
-	CfgFile         string            `json:"cfgFile"`
+	URLPath              string            `json:"urlPath"`
+	Parameters           []string          `json:"parameters"`
+	KeyValues            map[string]string `json:"keyValues"`
+	CfgTemplateName      string            `json:"cfgTemplateName"`
+	CfgFile              string            `json:"cfgFile"`
+	ConfigureFileContent string            `json:"-"`
+	FromFileParams       []string          `json:"-"`
 
 	// VolumeExpansion options.
 	// VCTNames VolumeClaimTemplate names
 	VCTNames []string `json:"vctNames,omitempty"`
 	Storage  string   `json:"storage"`
+
+	// RebuildInstance options
+	InstanceNames          []string          `json:"instanceNames,omitempty"`
+	BackupName             string            `json:"backupName,omitempty"`
+	SourceBackupTargetName string            `json:"sourceBackupTargetName,omitempty"`
+	TargetNodeName         string            `json:"targetNodeName,omitempty"`
+	EnvVars                []string          `json:"-"`
+	EnvKVs                 map[string]string `json:"envKVs,omitempty"`
+
+	// Switchover options
+	InstanceName  string `json:"instanceName,omitempty"`
+	CandidateRole string `json:"candidateRole,omitempty"`
+
+	// CustomOps options
+	OpName string   `json:"opsDefinitionName,omitempty"`
+	Params []string `json:"-"`
 }
 
 func newBaseOperationsOptions(streams genericclioptions.IOStreams, opsType dbaasv1alpha1.OpsType) *OperationsOptions {
@@ -93,6 +140,12 @@ var (
 
 		# update mysql max_connections, cluster name is mycluster
 		kbcli cluster configure mycluster --component-name=mysql --template-name=mysql-3node-tpl --configure-file=my.cnf --set max_connections=2000
+
+		# update component params from a local config file, parsed according to the template's ConfigConstraint format
+		kbcli cluster configure mycluster --component-name=mysql --template-name=mysql-3node-tpl --configure-file=my.cnf --configure-file-content=./my.cnf
+
+		# set a parameter's value from the content of a local file
+		kbcli cluster configure mycluster --component-name=redis --template-name=redis-tpl --configure-file=redis.conf --from-file=rename-command=@./rename-command.conf
 	`)
 )
 
@@ -103,6 +156,180 @@ func (o *OperationsOptions) buildCommonFlags(cmd *cobra.Command) {
 	if o.OpsType != dbaasv1alpha1.UpgradeType && o.OpsType != dbaasv1alpha1.ReconfiguringType {
 		cmd.Flags().StringSliceVar(&o.ComponentNames, "component-names", nil, " Component names to this operations")
 	}
+	cmd.Flags().StringVar(&o.DryRun, "dry-run", "none", `Must be "client", "server", or "none". If "client", only print the OpsRequest that would be sent, without sending it. If "server", submit the OpsRequest with server-side validation but no persistence.`)
+	cmd.Flags().StringVar(&o.Output, "output", "", `Preview output format, one of: yaml, json, diff. "diff" prints the Cluster YAML before and after the OpsRequest is applied, as their common prefix followed by the differing remainder of each ("A:" before, "B:" after), without submitting it; it is supported for vscale and hscale only.`)
+}
+
+// isPreviewOnly reports whether the command should only preview the OpsRequest rather than submit it.
+func (o *OperationsOptions) isPreviewOnly() bool {
+	return (len(o.DryRun) != 0 && o.DryRun != "none" && o.DryRun != "server") || o.Output == "diff" || o.Output == "yaml" || o.Output == "json"
+}
+
+// wrapDryRun makes every operations subcommand support --dry-run/--output so operators can preview
+// the OpsRequest an invocation would submit before actually running it, symmetric with kubectl
+// apply --dry-run. The preview branch still needs o.Client/o.Namespace populated, so this calls
+// the same base Complete step create.BuildCommand's RunE would have run, plus the per-type
+// completion that NewRestartCmd and NewReconfigureCmd register, before previewing.
+func wrapDryRun(f cmdutil.Factory, cmd *cobra.Command, o *OperationsOptions) *cobra.Command {
+	run := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if o.DryRun == "server" {
+			o.BaseOptions.DryRun = []string{metav1.DryRunAll}
+		}
+		if !o.isPreviewOnly() {
+			return run(cmd, args)
+		}
+		if err := o.Complete(f, args); err != nil {
+			return err
+		}
+		switch o.OpsType {
+		case dbaasv1alpha1.RestartType:
+			if err := o.CompleteRestartOps(); err != nil {
+				return err
+			}
+		case dbaasv1alpha1.ReconfiguringType:
+			if err := o.fillComponentNameForReconfiguring(); err != nil {
+				return err
+			}
+		}
+		if err := o.Validate(); err != nil {
+			return err
+		}
+		return o.previewOpsRequest()
+	}
+	return cmd
+}
+
+// previewOpsRequest renders the OpsRequest this invocation would submit, in the requested output
+// format, without submitting it.
+func (o *OperationsOptions) previewOpsRequest() error {
+	if o.Output == "diff" {
+		before, after, err := o.projectClusterChange()
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(o.Out, diff.StringDiff(before, after))
+		return nil
+	}
+	opsRequest, err := o.buildOpsRequestObject()
+	if err != nil {
+		return err
+	}
+	marshal := yaml.Marshal
+	if o.Output == "json" {
+		marshal = json.Marshal
+	}
+	data, err := marshal(opsRequest.Object)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(o.Out, string(data))
+	return nil
+}
+
+// buildOpsRequestObject renders the OpsRequest that this invocation would submit, the same object
+// cluster_operations_template.cue renders for actual submission, so previewOpsRequest shows
+// operators the real thing rather than a dump of CLI-internal options.
+func (o *OperationsOptions) buildOpsRequestObject() (*unstructured.Unstructured, error) {
+	o.OpsTypeLower = strings.ToLower(string(o.OpsType))
+	return create.BuildCueObject("cluster_operations_template.cue", o)
+}
+
+// projectClusterChange fetches the current Cluster CR and applies this operation's change onto a
+// copy, so --output=diff can show operators the effect of an OpsRequest before they submit it.
+func (o *OperationsOptions) projectClusterChange() (before string, after string, err error) {
+	gvr := schema.GroupVersionResource{Group: types.Group, Version: types.Version, Resource: types.ResourceClusters}
+	clusterObj, err := o.Client.Resource(gvr).Namespace(o.Namespace).Get(context.TODO(), o.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", "", err
+	}
+	beforeBytes, err := yaml.Marshal(clusterObj.Object)
+	if err != nil {
+		return "", "", err
+	}
+	before = string(beforeBytes)
+
+	projected := clusterObj.DeepCopy()
+	switch o.OpsType {
+	case dbaasv1alpha1.VerticalScalingType:
+		if err := o.projectVerticalScaling(projected); err != nil {
+			return "", "", err
+		}
+	case dbaasv1alpha1.HorizontalScalingType:
+		if err := o.projectHorizontalScaling(projected); err != nil {
+			return "", "", err
+		}
+	default:
+		// Every other OpsType either doesn't mutate the Cluster spec at all (e.g. reconfigure
+		// rewrites a ConfigMap, restart just triggers a rollout) or isn't projected here yet, so
+		// diffing the Cluster object would silently show "nothing will change" instead of an
+		// honest preview. Say so instead of returning a misleading no-op diff.
+		return "", "", fmt.Errorf("--output=diff is not supported for %s operations; use --output=yaml or --output=json instead", o.OpsType)
+	}
+	afterBytes, err := yaml.Marshal(projected.Object)
+	if err != nil {
+		return "", "", err
+	}
+	return before, string(afterBytes), nil
+}
+
+// projectVerticalScaling applies the requested resources onto the named components' ComponentSpec.
+func (o *OperationsOptions) projectVerticalScaling(cluster *unstructured.Unstructured) error {
+	componentSpecs, _, err := unstructured.NestedSlice(cluster.Object, "spec", "componentSpecs")
+	if err != nil {
+		return err
+	}
+	names := make(map[string]struct{}, len(o.ComponentNames))
+	for _, name := range o.ComponentNames {
+		names[name] = struct{}{}
+	}
+	for _, item := range componentSpecs {
+		spec, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(spec, "name")
+		if _, ok := names[name]; !ok {
+			continue
+		}
+		if len(o.RequestCPU) != 0 {
+			_ = unstructured.SetNestedField(spec, o.RequestCPU, "resources", "requests", "cpu")
+		}
+		if len(o.RequestMemory) != 0 {
+			_ = unstructured.SetNestedField(spec, o.RequestMemory, "resources", "requests", "memory")
+		}
+		if len(o.LimitCPU) != 0 {
+			_ = unstructured.SetNestedField(spec, o.LimitCPU, "resources", "limits", "cpu")
+		}
+		if len(o.LimitMemory) != 0 {
+			_ = unstructured.SetNestedField(spec, o.LimitMemory, "resources", "limits", "memory")
+		}
+	}
+	return unstructured.SetNestedSlice(cluster.Object, componentSpecs, "spec", "componentSpecs")
+}
+
+// projectHorizontalScaling applies the requested replica count onto the named components' ComponentSpec.
+func (o *OperationsOptions) projectHorizontalScaling(cluster *unstructured.Unstructured) error {
+	componentSpecs, _, err := unstructured.NestedSlice(cluster.Object, "spec", "componentSpecs")
+	if err != nil {
+		return err
+	}
+	names := make(map[string]struct{}, len(o.ComponentNames))
+	for _, name := range o.ComponentNames {
+		names[name] = struct{}{}
+	}
+	for _, item := range componentSpecs {
+		spec, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(spec, "name")
+		if _, ok := names[name]; !ok {
+			continue
+		}
+		_ = unstructured.SetNestedField(spec, int64(o.Replicas), "replicas")
+	}
+	return unstructured.SetNestedSlice(cluster.Object, componentSpecs, "spec", "componentSpecs")
 }
 
 // CompleteRestartOps when restart a cluster and component-names is null, represents restarting the entire cluster.
@@ -126,9 +353,79 @@ func (o *OperationsOptions) validateUpgrade() error {
 	if len(o.ClusterVersionRef) == 0 {
 		return fmt.Errorf("missing cluster-version")
 	}
+	if err := o.validateRolloutStrategy(); err != nil {
+		return err
+	}
 	return delete.Confirm([]string{o.Name}, o.In)
 }
 
+// validateRolloutStrategy requires the canary size to be consistent with the observed replicas of
+// the components the upgrade targets, fetched from cluster status the same way CompleteRestartOps
+// fetches status.operations.restartable, and scoped to --component-names if it was given (all
+// components otherwise).
+func (o *OperationsOptions) validateRolloutStrategy() error {
+	o.PauseBetweenSeconds = int(o.PauseBetween.Seconds())
+	if o.CanaryReplicas == 0 && o.CanaryPercent == 0 {
+		return nil
+	}
+	if o.CanaryReplicas != 0 && o.CanaryPercent != 0 {
+		return fmt.Errorf("only one of --canary-replicas or --canary-percent may be specified")
+	}
+	if o.CanaryPercent < 0 || o.CanaryPercent > 100 {
+		return fmt.Errorf("--canary-percent must be between 0 and 100")
+	}
+
+	gvr := schema.GroupVersionResource{Group: types.Group, Version: types.Version, Resource: types.ResourceClusters}
+	clusterObj, err := o.Client.Resource(gvr).Namespace(o.Namespace).Get(context.TODO(), o.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	components, _, err := unstructured.NestedMap(clusterObj.Object, "status", "components")
+	if err != nil {
+		return err
+	}
+	targets := o.ComponentNames
+	if len(targets) == 0 {
+		for name := range components {
+			targets = append(targets, name)
+		}
+	}
+	for _, name := range targets {
+		status, ok := components[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		replicas, _, _ := unstructured.NestedInt64(status, "replicas")
+		if o.CanaryReplicas != 0 && int64(o.CanaryReplicas) > replicas {
+			return fmt.Errorf("canary-replicas %d exceeds current replicas %d of component %s", o.CanaryReplicas, replicas, name)
+		}
+	}
+	return nil
+}
+
+// completeForRolloutPhase populates o.Client/o.Namespace for the --resume/--abort paths. Unlike
+// every other operations subcommand, --resume/--abort patch an existing OpsRequest by name and
+// take no cluster name positional argument, so they can't go through o.Complete, which parses
+// o.Name out of args[0].
+func (o *OperationsOptions) completeForRolloutPhase(f cmdutil.Factory) error {
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	o.Namespace = namespace
+	o.Client, err = f.DynamicClient()
+	return err
+}
+
+// patchRolloutPhase patches an existing Upgrade OpsRequest's rolloutStrategy to resume or abort
+// an in-progress canary rollout.
+func (o *OperationsOptions) patchRolloutPhase(opsName, phase string) error {
+	gvr := schema.GroupVersionResource{Group: types.Group, Version: types.Version, Resource: types.ResourceOpsRequests}
+	patchBytes := []byte(fmt.Sprintf(`{"spec":{"rolloutStrategy":{"phase":%q}}}`, phase))
+	_, err := o.Client.Resource(gvr).Namespace(o.Namespace).Patch(context.TODO(), opsName, apitypes.MergePatchType, patchBytes, metav1.PatchOptions{})
+	return err
+}
+
 func (o *OperationsOptions) validateVolumeExpansion() error {
 	if len(o.VCTNames) == 0 {
 		return fmt.Errorf("missing volume-claim-template-names")
@@ -146,6 +443,269 @@ func (o *OperationsOptions) validateHorizontalScaling() error {
 	return nil
 }
 
+// validateRebuildInstance checks that the target instances exist in the referenced component's
+// Pod list and, when a backup is specified, that the Backup exists and has completed.
+func (o *OperationsOptions) validateRebuildInstance() error {
+	if len(o.InstanceNames) == 0 {
+		return fmt.Errorf("missing instance names")
+	}
+	if len(o.ComponentNames) != 1 {
+		return fmt.Errorf("rebuild-instance only supports one component")
+	}
+	componentName := o.ComponentNames[0]
+
+	podGVR := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	podList, err := o.Client.Resource(podGVR).Namespace(o.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("app.kubernetes.io/instance=%s,app.kubernetes.io/component-name=%s", o.Name, componentName),
+	})
+	if err != nil {
+		return err
+	}
+	existingPods := make(map[string]struct{}, len(podList.Items))
+	for _, pod := range podList.Items {
+		existingPods[pod.GetName()] = struct{}{}
+	}
+	for _, name := range o.InstanceNames {
+		if _, ok := existingPods[name]; !ok {
+			return fmt.Errorf("instance %s does not exist in component %s", name, componentName)
+		}
+	}
+
+	if err := o.parseEnvVars(); err != nil {
+		return err
+	}
+
+	if len(o.BackupName) == 0 {
+		return nil
+	}
+	backupObj := dataprotectionv1alpha1.Backup{}
+	if err := util.GetResourceObjectFromGVR(types.BackupGVR(), client.ObjectKey{
+		Namespace: o.Namespace,
+		Name:      o.BackupName,
+	}, o.Client, &backupObj); err != nil {
+		return err
+	}
+	if backupObj.Status.Phase != dataprotectionv1alpha1.BackupCompleted {
+		return fmt.Errorf("backup %s is not completed yet", o.BackupName)
+	}
+	return nil
+}
+
+// parseEnvVars parses --env key=value pairs into EnvKVs, mirroring validateUpdatedParams.
+func (o *OperationsOptions) parseEnvVars() error {
+	o.EnvKVs = make(map[string]string, len(o.EnvVars))
+	for _, env := range o.EnvVars {
+		fields := strings.SplitN(env, "=", 2)
+		if len(fields) != 2 {
+			return fmt.Errorf("env var formatter: key=value")
+		}
+		o.EnvKVs[fields[0]] = fields[1]
+	}
+	return nil
+}
+
+// validateSwitchover checks that the referenced component supports a primary/leader switchover,
+// has more than one replica, and that the nominated instance is not already the primary/leader.
+func (o *OperationsOptions) validateSwitchover() error {
+	if len(o.InstanceName) == 0 {
+		return fmt.Errorf("missing instance name")
+	}
+	if len(o.ComponentNames) != 1 {
+		return fmt.Errorf("promote only supports one component")
+	}
+	componentName := o.ComponentNames[0]
+
+	clusterGVR := schema.GroupVersionResource{Group: types.Group, Version: types.Version, Resource: types.ResourceClusters}
+	clusterObj, err := o.Client.Resource(clusterGVR).Namespace(o.Namespace).Get(context.TODO(), o.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	componentSpecs, _, err := unstructured.NestedSlice(clusterObj.Object, "spec", "componentSpecs")
+	if err != nil {
+		return err
+	}
+	var (
+		componentDefRef string
+		replicas        int64
+	)
+	for _, item := range componentSpecs {
+		spec, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, _, _ := unstructured.NestedString(spec, "name"); name != componentName {
+			continue
+		}
+		componentDefRef, _, _ = unstructured.NestedString(spec, "componentDefRef")
+		replicas, _, _ = unstructured.NestedInt64(spec, "replicas")
+		break
+	}
+	if len(componentDefRef) == 0 {
+		return fmt.Errorf("component %s is not found in cluster %s", componentName, o.Name)
+	}
+	if replicas <= 1 {
+		return fmt.Errorf("component %s has only one replica, switchover is not supported", componentName)
+	}
+
+	clusterDefRef, _, err := unstructured.NestedString(clusterObj.Object, "spec", "clusterDefinitionRef")
+	if err != nil {
+		return err
+	}
+	clusterDefGVR := schema.GroupVersionResource{Group: types.Group, Version: types.Version, Resource: types.ResourceClusterDefs}
+	clusterDefObj := unstructured.Unstructured{}
+	if err := util.GetResourceObjectFromGVR(clusterDefGVR, client.ObjectKey{Name: clusterDefRef}, o.Client, &clusterDefObj); err != nil {
+		return err
+	}
+	components, _, err := unstructured.NestedSlice(clusterDefObj.Object, "spec", "components")
+	if err != nil {
+		return err
+	}
+	var workloadType string
+	for _, item := range components {
+		comp, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if typeName, _, _ := unstructured.NestedString(comp, "typeName"); typeName == componentDefRef {
+			workloadType, _, _ = unstructured.NestedString(comp, "workloadType")
+			break
+		}
+	}
+	if workloadType != "Consensus" && workloadType != "Replication" {
+		return fmt.Errorf("component %s's workloadType %s does not support switchover", componentName, workloadType)
+	}
+
+	leaderPath := []string{"status", "components", componentName, "consensusSetStatus", "leader", "pod"}
+	if workloadType == "Replication" {
+		leaderPath = []string{"status", "components", componentName, "replicationSetStatus", "primary", "pod"}
+	}
+	currentLeader, _, err := unstructured.NestedString(clusterObj.Object, leaderPath...)
+	if err != nil {
+		return err
+	}
+	if currentLeader == o.InstanceName {
+		return fmt.Errorf("instance %s is already the primary/leader of component %s", o.InstanceName, componentName)
+	}
+	return nil
+}
+
+// validateCustomOps loads the named custom operation from the component's ComponentDefinition and
+// coerces each --params key/value pair against the operation's parametersSchema.
+func (o *OperationsOptions) validateCustomOps() error {
+	if len(o.OpName) == 0 {
+		return fmt.Errorf("missing op-name")
+	}
+	if len(o.ComponentNames) != 1 {
+		return fmt.Errorf("custom-ops only supports one component")
+	}
+	componentName := o.ComponentNames[0]
+
+	o.KeyValues = make(map[string]string, len(o.Params))
+	for _, param := range o.Params {
+		fields := strings.SplitN(param, "=", 2)
+		if len(fields) != 2 {
+			return fmt.Errorf("params formatter: key=value")
+		}
+		o.KeyValues[fields[0]] = fields[1]
+	}
+
+	componentDefRef, err := o.getComponentDefRef(componentName)
+	if err != nil {
+		return err
+	}
+
+	componentDef := dbaasv1alpha1.ComponentDefinition{}
+	if err := util.GetResourceObjectFromGVR(types.ComponentDefinitionGVR(), client.ObjectKey{
+		Namespace: o.Namespace,
+		Name:      componentDefRef,
+	}, o.Client, &componentDef); err != nil {
+		return err
+	}
+
+	var customOpsDef *dbaasv1alpha1.CustomOpsDefinition
+	for i := range componentDef.Spec.CustomOpsDefinitions {
+		if componentDef.Spec.CustomOpsDefinitions[i].Name == o.OpName {
+			customOpsDef = &componentDef.Spec.CustomOpsDefinitions[i]
+			break
+		}
+	}
+	if customOpsDef == nil {
+		return fmt.Errorf("op %s is not defined in component definition %s", o.OpName, componentDefRef)
+	}
+	return validateParamsAgainstSchema(o.KeyValues, customOpsDef.ParametersSchema)
+}
+
+// getComponentDefRef resolves the componentDefRef a component in this cluster points to.
+func (o *OperationsOptions) getComponentDefRef(componentName string) (string, error) {
+	clusterGVR := schema.GroupVersionResource{Group: types.Group, Version: types.Version, Resource: types.ResourceClusters}
+	clusterObj, err := o.Client.Resource(clusterGVR).Namespace(o.Namespace).Get(context.TODO(), o.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	componentSpecs, _, err := unstructured.NestedSlice(clusterObj.Object, "spec", "componentSpecs")
+	if err != nil {
+		return "", err
+	}
+	for _, item := range componentSpecs {
+		spec, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, _, _ := unstructured.NestedString(spec, "name"); name != componentName {
+			continue
+		}
+		componentDefRef, _, _ := unstructured.NestedString(spec, "componentDefRef")
+		return componentDefRef, nil
+	}
+	return "", fmt.Errorf("component %s is not found in cluster %s", componentName, o.Name)
+}
+
+// validateParamsAgainstSchema rejects unknown parameters, requires required ones, and coerces
+// each value's type (string/integer/enum) against the JSON schema declared for the custom op.
+func validateParamsAgainstSchema(params map[string]string, schema apiextensionsv1.JSONSchemaProps) error {
+	for name := range params {
+		if _, ok := schema.Properties[name]; !ok {
+			return fmt.Errorf("unknown param %s", name)
+		}
+	}
+	for _, required := range schema.Required {
+		if _, ok := params[required]; !ok {
+			return fmt.Errorf("missing required param %s", required)
+		}
+	}
+	for name, value := range params {
+		prop := schema.Properties[name]
+		switch prop.Type {
+		case "integer":
+			if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+				return fmt.Errorf("param %s must be an integer, got %q", name, value)
+			}
+		case "number":
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return fmt.Errorf("param %s must be a number, got %q", name, value)
+			}
+		case "boolean":
+			if _, err := strconv.ParseBool(value); err != nil {
+				return fmt.Errorf("param %s must be a boolean, got %q", name, value)
+			}
+		}
+		if len(prop.Enum) > 0 {
+			var matched bool
+			for _, e := range prop.Enum {
+				if string(e.Raw) == fmt.Sprintf("%q", value) || string(e.Raw) == value {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return fmt.Errorf("param %s must be one of the enum values, got %q", name, value)
+			}
+		}
+	}
+	return nil
+}
+
 func (o *OperationsOptions) validateReconfiguring() error {
 	if len(o.ComponentNames) != 1 {
 		return cfgcore.MakeError("reconfiguring only support one component.")
@@ -157,10 +717,6 @@ func (o *OperationsOptions) validateReconfiguring() error {
 		return nil
 	}
 
-	if err := o.validateUpdatedParams(); err != nil {
-		return cfgcore.WrapError(err, "failed to validate updated params.")
-	}
-
 	componentName := o.ComponentNames[0]
 	tplList, err := util.GetConfigTemplateList(o.Name, o.Namespace, o.Client, componentName)
 	if err != nil {
@@ -170,6 +726,11 @@ func (o *OperationsOptions) validateReconfiguring() error {
 	if err != nil {
 		return err
 	}
+
+	if err := o.validateUpdatedParams(tpl); err != nil {
+		return cfgcore.WrapError(err, "failed to validate updated params.")
+	}
+
 	if err := o.validateConfigMapKey(tpl, componentName); err != nil {
 		return err
 	}
@@ -260,8 +821,8 @@ func (o *OperationsOptions) validateConfigMapKey(tpl *dbaasv1alpha1.ConfigTempla
 	return nil
 }
 
-func (o *OperationsOptions) validateUpdatedParams() error {
-	if len(o.Parameters) == 0 && len(o.URLPath) == 0 {
+func (o *OperationsOptions) validateUpdatedParams(tpl *dbaasv1alpha1.ConfigTemplate) error {
+	if len(o.Parameters) == 0 && len(o.URLPath) == 0 && len(o.ConfigureFileContent) == 0 && len(o.FromFileParams) == 0 {
 		return cfgcore.MakeError("reconfiguring required configure file or updated parameters.")
 	}
 
@@ -276,9 +837,193 @@ func (o *OperationsOptions) validateUpdatedParams() error {
 			o.KeyValues[fields[0]] = fields[1]
 		}
 	}
+
+	if len(o.ConfigureFileContent) != 0 {
+		parsedParams, err := o.parseConfigureFileContent(tpl)
+		if err != nil {
+			return err
+		}
+		for key, value := range parsedParams {
+			o.KeyValues[key] = value
+		}
+	}
+
+	for _, fromFile := range o.FromFileParams {
+		fields := strings.SplitN(fromFile, "=@", 2)
+		if len(fields) != 2 {
+			return cfgcore.MakeError("from-file formatter: key=@path")
+		}
+		content, err := os.ReadFile(fields[1])
+		if err != nil {
+			return cfgcore.WrapError(err, "failed to read %s", fields[1])
+		}
+		o.KeyValues[fields[0]] = string(content)
+	}
 	return nil
 }
 
+// parseConfigureFileContent parses --configure-file-content into a flat map of parameter path to
+// value, using the decoder matching the referenced ConfigConstraint's FormatterConfig.Format.
+func (o *OperationsOptions) parseConfigureFileContent(tpl *dbaasv1alpha1.ConfigTemplate) (map[string]string, error) {
+	content, err := os.ReadFile(o.ConfigureFileContent)
+	if err != nil {
+		return nil, cfgcore.WrapError(err, "failed to read %s", o.ConfigureFileContent)
+	}
+	configConstraint := dbaasv1alpha1.ConfigConstraint{}
+	if err := util.GetResourceObjectFromGVR(types.ConfigConstraintGVR(), client.ObjectKey{
+		Namespace: "",
+		Name:      tpl.ConfigConstraintRef,
+	}, o.Client, &configConstraint); err != nil {
+		return nil, err
+	}
+
+	switch configConstraint.Spec.FormatterConfig.Format {
+	case dbaasv1alpha1.INI:
+		return parseINIParams(content)
+	case dbaasv1alpha1.YAML:
+		return parseYAMLParams(content)
+	case dbaasv1alpha1.JSON:
+		return parseJSONParams(content)
+	case dbaasv1alpha1.Properties:
+		return parsePropertiesParams(content)
+	case dbaasv1alpha1.HCL:
+		return parseHCLParams(content)
+	case dbaasv1alpha1.XML:
+		return parseXMLParams(content)
+	default:
+		return nil, fmt.Errorf("unsupported config format %s for --configure-file-content", configConstraint.Spec.FormatterConfig.Format)
+	}
+}
+
+func parseINIParams(content []byte) (map[string]string, error) {
+	f, err := ini.Load(content)
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]string{}
+	for _, section := range f.Sections() {
+		for _, key := range section.Keys() {
+			if section.Name() == ini.DefaultSection {
+				params[key.Name()] = key.Value()
+				continue
+			}
+			params[fmt.Sprintf("%s.%s", section.Name(), key.Name())] = key.Value()
+		}
+	}
+	return params, nil
+}
+
+func parseYAMLParams(content []byte) (map[string]string, error) {
+	var data map[string]interface{}
+	if err := yaml.Unmarshal(content, &data); err != nil {
+		return nil, err
+	}
+	params := map[string]string{}
+	flattenParams("", data, params)
+	return params, nil
+}
+
+func parseJSONParams(content []byte) (map[string]string, error) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, err
+	}
+	params := map[string]string{}
+	flattenParams("", data, params)
+	return params, nil
+}
+
+func parsePropertiesParams(content []byte) (map[string]string, error) {
+	params := map[string]string{}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		fields := strings.SplitN(line, "=", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		params[strings.TrimSpace(fields[0])] = strings.TrimSpace(fields[1])
+	}
+	return params, nil
+}
+
+func parseHCLParams(content []byte) (map[string]string, error) {
+	var data map[string]interface{}
+	if err := hcl.Unmarshal(content, &data); err != nil {
+		return nil, err
+	}
+	params := map[string]string{}
+	flattenParams("", data, params)
+	return params, nil
+}
+
+// xmlNode decodes an arbitrary XML element tree, since the set of elements in a config file isn't
+// known ahead of time.
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Content string     `xml:",chardata"`
+	Nodes   []xmlNode  `xml:",any"`
+}
+
+func parseXMLParams(content []byte) (map[string]string, error) {
+	var root xmlNode
+	if err := xml.Unmarshal(content, &root); err != nil {
+		return nil, err
+	}
+	params := map[string]string{}
+	flattenXMLNode("", root, params)
+	return params, nil
+}
+
+// flattenXMLNode flattens an XML element tree into the same dot-separated parameter paths
+// flattenParams produces for the map-based formats, with attributes flattened the same way as
+// child elements.
+func flattenXMLNode(prefix string, node xmlNode, out map[string]string) {
+	path := node.XMLName.Local
+	if len(prefix) != 0 {
+		path = prefix + "." + path
+	}
+	for _, attr := range node.Attrs {
+		out[path+"."+attr.Name.Local] = attr.Value
+	}
+	if len(node.Nodes) == 0 {
+		if content := strings.TrimSpace(node.Content); len(content) != 0 || len(node.Attrs) == 0 {
+			out[path] = content
+		}
+		return
+	}
+	for _, child := range node.Nodes {
+		flattenXMLNode(path, child, out)
+	}
+}
+
+// flattenParams flattens a nested config document into the dot-separated parameter paths that
+// cfgcore.MergeAndValidateConfiguration expects as map keys.
+func flattenParams(prefix string, value interface{}, out map[string]string) {
+	// HCL decodes each nested block as a list of maps; flatten every block under the same prefix.
+	if blocks, ok := value.([]map[string]interface{}); ok {
+		for _, block := range blocks {
+			flattenParams(prefix, block, out)
+		}
+		return
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		out[prefix] = fmt.Sprintf("%v", value)
+		return
+	}
+	for key, val := range m {
+		path := key
+		if len(prefix) != 0 {
+			path = prefix + "." + key
+		}
+		flattenParams(path, val, out)
+	}
+}
+
 // Validate command flags or args is legal
 func (o *OperationsOptions) Validate() error {
 	if o.Name == "" {
@@ -307,6 +1052,18 @@ func (o *OperationsOptions) Validate() error {
 		if err := o.validateReconfiguring(); err != nil {
 			return err
 		}
+	case dbaasv1alpha1.RebuildInstanceType:
+		if err := o.validateRebuildInstance(); err != nil {
+			return err
+		}
+	case dbaasv1alpha1.SwitchoverType:
+		if err := o.validateSwitchover(); err != nil {
+			return err
+		}
+	case dbaasv1alpha1.CustomOpsType:
+		if err := o.validateCustomOps(); err != nil {
+			return err
+		}
 	}
 	return delete.Confirm([]string{o.Name}, o.In)
 }
@@ -362,12 +1119,21 @@ func NewRestartCmd(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobr
 		o.buildCommonFlags(cmd)
 	}
 	inputs.Complete = o.CompleteRestartOps
-	return create.BuildCommand(inputs)
+	return wrapDryRun(f, create.BuildCommand(inputs), o)
 }
 
 var upgradeExample = templates.Examples(`
-		# upgrade the cluster to the specified version 
+		# upgrade the cluster to the specified version
 		kbcli cluster upgrade <my-cluster> --cluster-version=<cluster-version>
+
+		# upgrade the cluster progressively, pausing after the first replica is upgraded
+		kbcli cluster upgrade <my-cluster> --cluster-version=<cluster-version> --canary-replicas=1 --pause-between=5m
+
+		# resume a paused canary upgrade once the canary replica is verified healthy
+		kbcli cluster upgrade --resume <ops-request-name>
+
+		# abort a canary upgrade and roll back the already-upgraded replicas
+		kbcli cluster upgrade --abort <ops-request-name>
 `)
 
 // NewUpgradeCmd create a upgrade command
@@ -380,8 +1146,33 @@ func NewUpgradeCmd(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobr
 	inputs.BuildFlags = func(cmd *cobra.Command) {
 		o.buildCommonFlags(cmd)
 		cmd.Flags().StringVar(&o.ClusterVersionRef, "cluster-version", "", "Reference cluster version (required)")
+		cmd.Flags().StringSliceVar(&o.ComponentNames, "component-names", nil, "Component names to scope --canary-replicas/--canary-percent validation to (default: all components)")
+		cmd.Flags().IntVar(&o.CanaryReplicas, "canary-replicas", 0, "Number of replicas to upgrade first before pausing for verification")
+		cmd.Flags().IntVar(&o.CanaryPercent, "canary-percent", 0, "Percentage of replicas to upgrade first before pausing for verification")
+		cmd.Flags().DurationVar(&o.PauseBetween, "pause-between", 0, "Duration to pause between rollout batches")
+		cmd.Flags().StringVar(&o.MaxSurge, "max-surge", "", "Maximum number of replicas that can be created above the desired replicas during the rollout")
+		cmd.Flags().StringVar(&o.MaxUnavailable, "max-unavailable", "", "Maximum number of replicas that can be unavailable during the rollout")
+		cmd.Flags().BoolVar(&o.AutoRollbackOnFailure, "auto-rollback-on-failure", false, "Automatically roll back the upgrade if a rollout batch fails")
+		cmd.Flags().StringVar(&o.ResumeOpsName, "resume", "", "Resume a paused canary upgrade, specify the name of its OpsRequest")
+		cmd.Flags().StringVar(&o.AbortOpsName, "abort", "", "Abort a canary upgrade and roll back, specify the name of its OpsRequest")
 	}
-	return create.BuildCommand(inputs)
+	cmd := wrapDryRun(f, create.BuildCommand(inputs), o)
+	run := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(o.ResumeOpsName) != 0 || len(o.AbortOpsName) != 0 {
+			if err := o.completeForRolloutPhase(f); err != nil {
+				return err
+			}
+		}
+		if len(o.ResumeOpsName) != 0 {
+			return o.patchRolloutPhase(o.ResumeOpsName, "Continuing")
+		}
+		if len(o.AbortOpsName) != 0 {
+			return o.patchRolloutPhase(o.AbortOpsName, "Rollback")
+		}
+		return run(cmd, args)
+	}
+	return cmd
 }
 
 var verticalScalingExample = templates.Examples(`
@@ -404,7 +1195,7 @@ func NewVerticalScalingCmd(f cmdutil.Factory, streams genericclioptions.IOStream
 		cmd.Flags().StringVar(&o.LimitCPU, "limits.cpu", "", "CPU size limited by the component")
 		cmd.Flags().StringVar(&o.LimitMemory, "limits.memory", "", "Memory size limited by the component")
 	}
-	return create.BuildCommand(inputs)
+	return wrapDryRun(f, create.BuildCommand(inputs), o)
 }
 
 var horizontalScalingExample = templates.Examples(`
@@ -423,7 +1214,7 @@ func NewHorizontalScalingCmd(f cmdutil.Factory, streams genericclioptions.IOStre
 		o.buildCommonFlags(cmd)
 		cmd.Flags().IntVar(&o.Replicas, "replicas", -1, "Replicas with the specified components")
 	}
-	return create.BuildCommand(inputs)
+	return wrapDryRun(f, create.BuildCommand(inputs), o)
 }
 
 var volumeExpansionExample = templates.Examples(`
@@ -444,7 +1235,7 @@ func NewVolumeExpansionCmd(f cmdutil.Factory, streams genericclioptions.IOStream
 		cmd.Flags().StringSliceVar(&o.VCTNames, "volume-claim-template-names", nil, "VolumeClaimTemplate names in components (required)")
 		cmd.Flags().StringVar(&o.Storage, "storage", "", "Volume storage size (required)")
 	}
-	return create.BuildCommand(inputs)
+	return wrapDryRun(f, create.BuildCommand(inputs), o)
 }
 
 // NewReconfigureCmd create a Reconfiguring command
@@ -460,7 +1251,85 @@ func NewReconfigureCmd(f cmdutil.Factory, streams genericclioptions.IOStreams) *
 		cmd.Flags().StringSliceVar(&o.ComponentNames, "component-name", nil, "Specify the name of Component to be updated. If the cluster has only one component, unset the parameter.")
 		cmd.Flags().StringVar(&o.CfgTemplateName, "template-name", "", "Specify the name of the configuration template to be updated (e.g. for apecloud-mysql: --template-name=mysql-3node-tpl). What templates or configure files are available for this cluster can refer to kbcli sub command: 'kbcli cluster describe-configure'.")
 		cmd.Flags().StringVar(&o.CfgFile, "configure-file", "", "Specify the name of the configuration file to be updated (e.g. for mysql: --configure-file=my.cnf). What templates or configure files are available for this cluster can refer to kbcli sub command: 'kbcli cluster describe-configure'.")
+		cmd.Flags().StringVar(&o.ConfigureFileContent, "configure-file-content", "", "Specify a local config file (e.g. my.cnf/redis.conf) whose parameters are merged into the updated parameters, overriding --set values for the same key; it is parsed according to the configuration template's ConfigConstraint format (ini, yaml, json, properties, hcl or xml).")
+		cmd.Flags().StringSliceVar(&o.FromFileParams, "from-file", nil, "Set a parameter's value from a local file, such as --from-file=key=@path. Only valid for parameters whose schema type is object or string.")
 	}
 	inputs.Complete = o.fillComponentNameForReconfiguring
-	return create.BuildCommand(inputs)
+	return wrapDryRun(f, create.BuildCommand(inputs), o)
+}
+
+var rebuildInstanceExample = templates.Examples(`
+		# rebuild instance, this will delete the instance and restore it from the latest backup
+		kbcli cluster rebuild-instance <my-cluster> --component-names=<component-name> --instance=<instance-name>
+
+		# rebuild more than one instances
+		kbcli cluster rebuild-instance <my-cluster> --component-names=<component-name> --instance=<instance-name-1>,<instance-name-2>
+
+		# rebuild instance and restore it from a specified backup
+		kbcli cluster rebuild-instance <my-cluster> --component-names=<component-name> --instance=<instance-name> --backup=<backup-name>
+`)
+
+// NewRebuildInstanceCmd create a rebuild-instance command
+func NewRebuildInstanceCmd(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := newBaseOperationsOptions(streams, dbaasv1alpha1.RebuildInstanceType)
+	inputs := buildOperationsInputs(f, o)
+	inputs.Use = "rebuild-instance"
+	inputs.Short = "Rebuild the specified instances in the cluster"
+	inputs.Example = rebuildInstanceExample
+	inputs.BuildFlags = func(cmd *cobra.Command) {
+		o.buildCommonFlags(cmd)
+		cmd.Flags().StringSliceVar(&o.InstanceNames, "instance", nil, "Instance names that need to rebuild (required)")
+		cmd.Flags().StringVar(&o.BackupName, "backup", "", "Backup name to restore data from when rebuilding the instance")
+		cmd.Flags().StringVar(&o.SourceBackupTargetName, "source-target", "", "Source target name in the backup to restore from, required when the backup has multiple targets")
+		cmd.Flags().StringVar(&o.TargetNodeName, "target-node-name", "", "Node name where the rebuilt instance will be scheduled")
+		cmd.Flags().StringSliceVar(&o.EnvVars, "env", nil, "Set env vars that will be injected into the rebuilt instance, such as --env=key1=value1,key2=value2")
+	}
+	return wrapDryRun(f, create.BuildCommand(inputs), o)
+}
+
+var promoteExample = templates.Examples(`
+		# promote a non-primary or non-leader instance as the new primary or leader of the cluster
+		kbcli cluster promote <my-cluster> --component-names=<component-name> --instance=<instance-name>
+
+		# promote a non-primary or non-leader instance as the new primary or leader, and specify a candidate role
+		kbcli cluster promote <my-cluster> --component-names=<component-name> --instance=<instance-name> --candidate-role=<candidate-role>
+`)
+
+// NewPromoteCmd create a promote command to do switchover
+func NewPromoteCmd(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := newBaseOperationsOptions(streams, dbaasv1alpha1.SwitchoverType)
+	inputs := buildOperationsInputs(f, o)
+	inputs.Use = "promote"
+	inputs.Short = "Promote a non-primary or non-leader instance as the new primary or leader of the cluster"
+	inputs.Example = promoteExample
+	inputs.BuildFlags = func(cmd *cobra.Command) {
+		o.buildCommonFlags(cmd)
+		cmd.Flags().StringVar(&o.InstanceName, "instance", "", "Instance name that will become the new primary or leader (required)")
+		cmd.Flags().StringVar(&o.CandidateRole, "candidate-role", "", "Candidate role to promote, for engines that support roles other than primary/leader")
+	}
+	return wrapDryRun(f, create.BuildCommand(inputs), o)
+}
+
+var customOpsExample = templates.Examples(`
+		# run the "flush-privileges" custom op declared by the apecloud-mysql ComponentDefinition
+		kbcli cluster custom-ops <my-cluster> --component-names=<component-name> --op-name=flush-privileges
+
+		# run a custom op that takes parameters
+		kbcli cluster custom-ops <my-cluster> --component-names=<component-name> --op-name=reassign-partitions --params=topic=my-topic,replicas=3
+`)
+
+// NewCustomOpsCmd create a custom-ops command that runs an engine-specific operation declared by
+// the component's ComponentDefinition
+func NewCustomOpsCmd(f cmdutil.Factory, streams genericclioptions.IOStreams) *cobra.Command {
+	o := newBaseOperationsOptions(streams, dbaasv1alpha1.CustomOpsType)
+	inputs := buildOperationsInputs(f, o)
+	inputs.Use = "custom-ops"
+	inputs.Short = "Run a custom operation declared by the component's ComponentDefinition"
+	inputs.Example = customOpsExample
+	inputs.BuildFlags = func(cmd *cobra.Command) {
+		o.buildCommonFlags(cmd)
+		cmd.Flags().StringVar(&o.OpName, "op-name", "", "Name of the custom op declared in the ComponentDefinition (required)")
+		cmd.Flags().StringSliceVar(&o.Params, "params", nil, "Params to pass to the custom op, such as --params=key1=value1,key2=value2")
+	}
+	return wrapDryRun(f, create.BuildCommand(inputs), o)
 }
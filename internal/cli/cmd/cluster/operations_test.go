@@ -0,0 +1,210 @@
+/*
+Copyright ApeCloud, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newFakeClusterObj(componentSpecs ...map[string]interface{}) *unstructured.Unstructured {
+	specs := make([]interface{}, 0, len(componentSpecs))
+	for _, spec := range componentSpecs {
+		specs = append(specs, spec)
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"componentSpecs": specs,
+		},
+	}}
+}
+
+func TestProjectVerticalScaling(t *testing.T) {
+	cluster := newFakeClusterObj(
+		map[string]interface{}{"name": "mysql", "replicas": int64(3)},
+		map[string]interface{}{"name": "redis", "replicas": int64(1)},
+	)
+	o := &OperationsOptions{ComponentNames: []string{"mysql"}, RequestCPU: "500m", LimitMemory: "1Gi"}
+	if err := o.projectVerticalScaling(cluster); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	componentSpecs, _, _ := unstructured.NestedSlice(cluster.Object, "spec", "componentSpecs")
+	mysql := componentSpecs[0].(map[string]interface{})
+	cpu, _, _ := unstructured.NestedString(mysql, "resources", "requests", "cpu")
+	if cpu != "500m" {
+		t.Errorf("expected requests.cpu=500m for mysql, got %q", cpu)
+	}
+	memory, _, _ := unstructured.NestedString(mysql, "resources", "limits", "memory")
+	if memory != "1Gi" {
+		t.Errorf("expected limits.memory=1Gi for mysql, got %q", memory)
+	}
+	redis := componentSpecs[1].(map[string]interface{})
+	if _, found, _ := unstructured.NestedString(redis, "resources", "requests", "cpu"); found {
+		t.Errorf("expected redis to be untouched, got requests.cpu set")
+	}
+}
+
+func TestProjectHorizontalScaling(t *testing.T) {
+	cluster := newFakeClusterObj(
+		map[string]interface{}{"name": "mysql", "replicas": int64(3)},
+		map[string]interface{}{"name": "redis", "replicas": int64(1)},
+	)
+	o := &OperationsOptions{ComponentNames: []string{"mysql"}, Replicas: 5}
+	if err := o.projectHorizontalScaling(cluster); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	componentSpecs, _, _ := unstructured.NestedSlice(cluster.Object, "spec", "componentSpecs")
+	mysql := componentSpecs[0].(map[string]interface{})
+	replicas, _, _ := unstructured.NestedInt64(mysql, "replicas")
+	if replicas != 5 {
+		t.Errorf("expected mysql replicas=5, got %d", replicas)
+	}
+	redis := componentSpecs[1].(map[string]interface{})
+	replicas, _, _ = unstructured.NestedInt64(redis, "replicas")
+	if replicas != 1 {
+		t.Errorf("expected redis replicas to remain 1, got %d", replicas)
+	}
+}
+
+func TestValidateParamsAgainstSchema(t *testing.T) {
+	schema := apiextensionsv1.JSONSchemaProps{
+		Required: []string{"topic"},
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"topic":    {Type: "string"},
+			"replicas": {Type: "integer"},
+			"dry-run":  {Type: "boolean"},
+			"level":    {Type: "string", Enum: []apiextensionsv1.JSON{{Raw: []byte(`"low"`)}, {Raw: []byte(`"high"`)}}},
+		},
+	}
+
+	cases := []struct {
+		name    string
+		params  map[string]string
+		wantErr bool
+	}{
+		{name: "valid", params: map[string]string{"topic": "my-topic", "replicas": "3"}},
+		{name: "unknown param", params: map[string]string{"bogus": "1"}, wantErr: true},
+		{name: "missing required", params: map[string]string{"replicas": "3"}, wantErr: true},
+		{name: "bad integer", params: map[string]string{"topic": "t", "replicas": "abc"}, wantErr: true},
+		{name: "bad boolean", params: map[string]string{"topic": "t", "dry-run": "maybe"}, wantErr: true},
+		{name: "enum match", params: map[string]string{"topic": "t", "level": "low"}},
+		{name: "enum mismatch", params: map[string]string{"topic": "t", "level": "medium"}, wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateParamsAgainstSchema(c.params, schema)
+			if c.wantErr && err == nil {
+				t.Errorf("expected an error, got nil")
+			}
+			if !c.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseConfigFormatParams(t *testing.T) {
+	cases := []struct {
+		name    string
+		parse   func([]byte) (map[string]string, error)
+		content string
+		want    map[string]string
+	}{
+		{
+			name:    "ini",
+			parse:   parseINIParams,
+			content: "max_connections=100\n\n[mysqld]\ngeneral_log=OFF\n",
+			want:    map[string]string{"max_connections": "100", "mysqld.general_log": "OFF"},
+		},
+		{
+			name:    "yaml",
+			parse:   parseYAMLParams,
+			content: "mysqld:\n  max_connections: 100\n",
+			want:    map[string]string{"mysqld.max_connections": "100"},
+		},
+		{
+			name:    "json",
+			parse:   parseJSONParams,
+			content: `{"mysqld":{"max_connections":100}}`,
+			want:    map[string]string{"mysqld.max_connections": "100"},
+		},
+		{
+			name:    "properties",
+			parse:   parsePropertiesParams,
+			content: "# comment\nmax_connections=100\n! bang comment\ngeneral_log = OFF\n",
+			want:    map[string]string{"max_connections": "100", "general_log": "OFF"},
+		},
+		{
+			name:    "hcl",
+			parse:   parseHCLParams,
+			content: `mysqld { max_connections = 100 }`,
+			want:    map[string]string{"mysqld.max_connections": "100"},
+		},
+		{
+			name:    "xml",
+			parse:   parseXMLParams,
+			content: `<config><mysqld max_connections="100"><general_log>OFF</general_log></mysqld></config>`,
+			want:    map[string]string{"config.mysqld.max_connections": "100", "config.mysqld.general_log": "OFF"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.parse([]byte(c.content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for k, want := range c.want {
+				if got[k] != want {
+					t.Errorf("expected %s=%q, got %q (all: %v)", k, want, got[k], got)
+				}
+			}
+		})
+	}
+}
+
+func TestFlattenParams(t *testing.T) {
+	out := map[string]string{}
+	flattenParams("", map[string]interface{}{
+		"a": 1,
+		"b": map[string]interface{}{
+			"c": "x",
+		},
+	}, out)
+	if out["a"] != "1" {
+		t.Errorf("expected a=1, got %q", out["a"])
+	}
+	if out["b.c"] != "x" {
+		t.Errorf("expected b.c=x, got %q", out["b.c"])
+	}
+}
+
+// TestPauseBetweenSecondsConversion ensures the OpsRequest renders pauseBetween in seconds, not
+// the raw nanoseconds time.Duration would otherwise marshal as.
+func TestPauseBetweenSecondsConversion(t *testing.T) {
+	o := &OperationsOptions{PauseBetween: 5 * time.Minute}
+	if err := o.validateRolloutStrategy(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if o.PauseBetweenSeconds != 300 {
+		t.Errorf("expected PauseBetweenSeconds=300, got %d", o.PauseBetweenSeconds)
+	}
+}